@@ -0,0 +1,254 @@
+// Package asm implements a two-pass assembler and disassembler for the
+// CHIP-8 mnemonics described in the Cowgod technical reference: CLS,
+// RET, JP, CALL, SE, SNE, LD, ADD, OR, AND, XOR, SUB, SHR, SUBN, SHL,
+// RND, DRW, SKP, SKNP, with operand forms Vx, Vy, byte, addr, nibble, I,
+// [I], DT, ST, K, F, and B. It also supports the SuperChip/XO-CHIP
+// extensions: SCD, SCR, SCL, EXIT, LOW, HIGH, PLANE, the HF/R operand
+// forms of LD, the "LD [I], Vx-Vy"/"LD Vx-Vy, [I]" register-range forms,
+// and the 4-byte "LD I, LONG nnnn" long address load.
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultOrigin is where assembled code starts absent an ORG directive,
+// matching where chip8.CPU.LoadRom places ROMs in memory.
+const defaultOrigin = 0x200
+
+// SymbolTable maps label names to the addresses they were defined at.
+type SymbolTable map[string]uint16
+
+// sourceLine is one parsed line of assembly: an optional label, an
+// optional opcode/directive with its operands, and the original text for
+// error messages.
+type sourceLine struct {
+	lineNo int
+	label  string
+	op     string
+	args   []string
+	raw    string
+}
+
+// Assemble compiles CHIP-8 assembly source into a ROM image suitable for
+// chip8.CPU.LoadRom. Assembly starts at 0x200 unless an ORG directive
+// says otherwise.
+func Assemble(src string) ([]byte, error) {
+	lines, err := parseLines(src)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols, err := resolveSymbols(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return encode(lines, symbols)
+}
+
+// parseLines splits source into sourceLines, stripping comments (';' to
+// end of line) and blank lines, and separating a leading "label:" from
+// the instruction or directive that may follow it on the same line.
+func parseLines(src string) ([]sourceLine, error) {
+	var out []sourceLine
+	for i, raw := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		text := raw
+		if idx := strings.IndexByte(text, ';'); idx >= 0 {
+			text = text[:idx]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		var label string
+		if idx := strings.IndexByte(text, ':'); idx >= 0 {
+			label = strings.TrimSpace(text[:idx])
+			text = strings.TrimSpace(text[idx+1:])
+			if label == "" {
+				return nil, fmt.Errorf("line %d: empty label", lineNo)
+			}
+		}
+
+		sl := sourceLine{lineNo: lineNo, label: label, raw: raw}
+		if text != "" {
+			fields := strings.SplitN(text, " ", 2)
+			sl.op = strings.ToUpper(fields[0])
+			if len(fields) == 2 {
+				for _, a := range strings.Split(fields[1], ",") {
+					sl.args = append(sl.args, strings.TrimSpace(a))
+				}
+			}
+		}
+		out = append(out, sl)
+	}
+	return out, nil
+}
+
+// resolveSymbols walks the source once, tracking the address each
+// instruction or directive will assemble to, and records where every
+// label points.
+func resolveSymbols(lines []sourceLine) (SymbolTable, error) {
+	symbols := SymbolTable{}
+	addr := uint16(defaultOrigin)
+
+	for _, l := range lines {
+		if l.label != "" {
+			if _, dup := symbols[l.label]; dup {
+				return nil, fmt.Errorf("line %d: label %q redefined", l.lineNo, l.label)
+			}
+			symbols[l.label] = addr
+		}
+		if l.op == "" {
+			continue
+		}
+
+		size, newAddr, err := sizeOf(l, addr)
+		if err != nil {
+			return nil, err
+		}
+		if newAddr != addr {
+			addr = newAddr
+			continue
+		}
+		addr += size
+	}
+	return symbols, nil
+}
+
+// sizeOf returns how many bytes l assembles to, or, for ORG, the new
+// current address as newAddr (for every other line newAddr is addr
+// unchanged).
+func sizeOf(l sourceLine, addr uint16) (size uint16, newAddr uint16, err error) {
+	switch l.op {
+	case "ORG":
+		v, err := parseNumber(argOrEmpty(l.args, 0))
+		if err != nil {
+			return 0, 0, fmt.Errorf("line %d: %w", l.lineNo, err)
+		}
+		return 0, uint16(v), nil
+	case ".BYTE":
+		return uint16(len(l.args)), addr, nil
+	case ".WORD":
+		return uint16(len(l.args)) * 2, addr, nil
+	default:
+		if isLongLoadI(l) {
+			return 4, addr, nil
+		}
+		return 2, addr, nil
+	}
+}
+
+// encode performs the actual assembly, now that every label's address is
+// known, and lays the result out into a contiguous ROM image starting
+// at the lower of 0x200 and the lowest address used, so code placed
+// above 0x200 by ORG is preceded by zero padding from 0x200.
+func encode(lines []sourceLine, symbols SymbolTable) ([]byte, error) {
+	image := map[uint16]byte{}
+	addr := uint16(defaultOrigin)
+	low, high := addr, addr
+
+	mark := func(a uint16) {
+		if a < low {
+			low = a
+		}
+		if a > high {
+			high = a
+		}
+	}
+
+	for _, l := range lines {
+		if l.op == "" {
+			continue
+		}
+
+		switch l.op {
+		case "ORG":
+			v, _ := parseNumber(l.args[0])
+			addr = uint16(v)
+			continue
+		case ".BYTE":
+			for _, a := range l.args {
+				v, err := parseNumber(a)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", l.lineNo, err)
+				}
+				image[addr] = byte(v)
+				mark(addr)
+				addr++
+			}
+			continue
+		case ".WORD":
+			for _, a := range l.args {
+				v, err := parseNumber(a)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", l.lineNo, err)
+				}
+				image[addr] = byte(v >> 8)
+				image[addr+1] = byte(v)
+				mark(addr)
+				mark(addr + 1)
+				addr += 2
+			}
+			continue
+		}
+
+		if isLongLoadI(l) {
+			nnnn, err := parseLongAddr(l.args[1])
+			if err != nil {
+				return nil, lineErr(l, err)
+			}
+			image[addr] = 0xF0
+			image[addr+1] = 0x00
+			image[addr+2] = byte(nnnn >> 8)
+			image[addr+3] = byte(nnnn)
+			mark(addr)
+			mark(addr + 3)
+			addr += 4
+			continue
+		}
+
+		opcode, err := encodeInstruction(l, addr, symbols)
+		if err != nil {
+			return nil, err
+		}
+		image[addr] = byte(opcode >> 8)
+		image[addr+1] = byte(opcode)
+		mark(addr)
+		mark(addr + 1)
+		addr += 2
+	}
+
+	if len(image) == 0 {
+		return nil, nil
+	}
+
+	rom := make([]byte, high-low+1)
+	for a, b := range image {
+		rom[a-low] = b
+	}
+	return rom, nil
+}
+
+func argOrEmpty(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func parseNumber(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		return strconv.ParseUint(s[2:], 16, 16)
+	case strings.HasPrefix(s, "$"):
+		return strconv.ParseUint(s[1:], 16, 16)
+	default:
+		return strconv.ParseUint(s, 10, 16)
+	}
+}
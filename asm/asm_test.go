@@ -0,0 +1,242 @@
+package asm
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// opcodes extracts the big-endian 16-bit opcodes out of a ROM image, for
+// tests that want to assert on instructions rather than raw bytes.
+func opcodes(t *testing.T, rom []byte) []uint16 {
+	t.Helper()
+	if len(rom)%2 != 0 {
+		t.Fatalf("rom length %d is not a multiple of 2", len(rom))
+	}
+	out := make([]uint16, len(rom)/2)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(rom[i*2 : i*2+2])
+	}
+	return out
+}
+
+func TestAssembleInstructions(t *testing.T) {
+	tests := []struct {
+		src  string
+		want uint16
+	}{
+		{"CLS", 0x00E0},
+		{"RET", 0x00EE},
+		{"JP 0x300", 0x1300},
+		{"CALL 0x300", 0x2300},
+		{"SE V1, 0x20", 0x3120},
+		{"SNE V1, 0x20", 0x4120},
+		{"SE V1, V2", 0x5120},
+		{"LD V1, 0x20", 0x6120},
+		{"ADD V1, 0x20", 0x7120},
+		{"LD V1, V2", 0x8120},
+		{"OR V1, V2", 0x8121},
+		{"AND V1, V2", 0x8122},
+		{"XOR V1, V2", 0x8123},
+		{"ADD V1, V2", 0x8124},
+		{"SUB V1, V2", 0x8125},
+		{"SHR V1", 0x8106},
+		{"SHR V1, V5", 0x8156},
+		{"SUBN V1, V2", 0x8127},
+		{"SHL V1", 0x810E},
+		{"SHL V1, V5", 0x815E},
+		{"SNE V1, V2", 0x9120},
+		{"LD I, 0x300", 0xA300},
+		{"JP V0, 0x300", 0xB300},
+		{"RND V1, 0x20", 0xC120},
+		{"DRW V1, V2, 0x5", 0xD125},
+		{"SKP V1", 0xE19E},
+		{"SKNP V1", 0xE1A1},
+		{"LD V1, DT", 0xF107},
+		{"LD V1, K", 0xF10A},
+		{"LD DT, V1", 0xF115},
+		{"LD ST, V1", 0xF118},
+		{"ADD I, V1", 0xF11E},
+		{"LD F, V1", 0xF129},
+		{"LD B, V1", 0xF133},
+		{"LD [I], V1", 0xF155},
+		{"LD V1, [I]", 0xF165},
+		{"SCD 0x4", 0x00C4},
+		{"SCR", 0x00FB},
+		{"SCL", 0x00FC},
+		{"EXIT", 0x00FD},
+		{"LOW", 0x00FE},
+		{"HIGH", 0x00FF},
+		{"LD [I], V1-V3", 0x5132},
+		{"LD V1-V3, [I]", 0x5133},
+		{"PLANE 0x1", 0xF101},
+		{"LD HF, V1", 0xF130},
+		{"LD R, V1", 0xF175},
+		{"LD V1, R", 0xF185},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			rom, err := Assemble(tt.src)
+			if err != nil {
+				t.Fatalf("Assemble(%q): %v", tt.src, err)
+			}
+			got := opcodes(t, rom)
+			if len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("Assemble(%q) = %04X, want [%04X]", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleLabels(t *testing.T) {
+	src := `
+loop:
+	ADD V0, 0x1
+	SE V0, 0xA
+	JP loop
+	RET
+`
+	rom, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	got := opcodes(t, rom)
+	want := []uint16{0x7001, 0x300A, 0x1200, 0x00EE}
+	if len(got) != len(want) {
+		t.Fatalf("got %d opcodes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("opcode %d = %04X, want %04X", i, got[i], want[i])
+		}
+	}
+}
+
+// TestDisassembleRoundTrip assembles a small program exercising the LD
+// operand forms, the shifts, DRW, and the SuperChip/XO-CHIP mnemonics,
+// disassembles it, then reassembles the disassembled text and
+// checks the bytes come back unchanged. This is the round trip that
+// caught the missing "LD F, Vx"/"LD B, Vx" destination forms: disasm.go
+// already emitted that text for Fx29/Fx33, but the assembler couldn't
+// read it back in.
+func TestDisassembleRoundTrip(t *testing.T) {
+	src := `
+	CLS
+	LD V0, 0x5
+	LD F, V0
+	LD B, V0
+	LD [I], V0
+	LD V0, [I]
+	DRW V0, V1, 0x5
+	SHR V1, V5
+	SHL V1, V5
+	SCD 0x4
+	SCR
+	SCL
+	EXIT
+	LOW
+	HIGH
+	PLANE 0x1
+	LD HF, V0
+	LD R, V0
+	LD V0, R
+	LD [I], V1-V3
+	LD V1-V3, [I]
+	LD I, LONG 0x0ABC
+	RET
+`
+	rom, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+
+	instructions, err := Disassemble(rom, defaultOrigin)
+	if err != nil {
+		t.Fatalf("Disassemble: %v", err)
+	}
+
+	var reassembled string
+	for _, in := range instructions {
+		reassembled += in.Text + "\n"
+	}
+
+	rom2, err := Assemble(reassembled)
+	if err != nil {
+		t.Fatalf("re-Assemble(%q): %v", reassembled, err)
+	}
+
+	if string(rom) != string(rom2) {
+		t.Fatalf("round trip mismatch:\noriginal:     % X\nreassembled:  % X\ndisassembly:\n%s", rom, rom2, reassembled)
+	}
+}
+
+// TestDisassembleShiftPreservesVy guards against SHR/SHL silently
+// dropping Vy: Quirks.ShiftUsesVy (the ChipClassic default) makes 8xy6
+// and 8xyE actually read Vy at runtime, so losing it during
+// disassemble-then-reassemble would change which register a ROM reads.
+func TestDisassembleShiftPreservesVy(t *testing.T) {
+	rom := []byte{0x81, 0x56} // SHR V1, V5
+	instructions, err := Disassemble(rom, defaultOrigin)
+	if err != nil {
+		t.Fatalf("Disassemble: %v", err)
+	}
+	if want := "SHR V1, V5"; instructions[0].Text != want {
+		t.Fatalf("Disassemble(0x8156) = %q, want %q", instructions[0].Text, want)
+	}
+
+	reassembled, err := Assemble(instructions[0].Text)
+	if err != nil {
+		t.Fatalf("Assemble(%q): %v", instructions[0].Text, err)
+	}
+	if got := opcodes(t, reassembled); len(got) != 1 || got[0] != 0x8156 {
+		t.Fatalf("Assemble(%q) = %04X, want [8156]", instructions[0].Text, got)
+	}
+}
+
+// TestAssembleInvalidRegisterOperand guards against a malformed register
+// operand being silently truncated to a valid prefix instead of
+// rejected: ParseHexDigit's underlying fmt.Sscanf stops at the first
+// non-hex character rather than requiring the whole operand to match, so
+// e.g. "V5X" used to parse as plain "V5", and a three-register typo like
+// "V1-V3-V5" used to parse as the range "V1-V3".
+func TestAssembleInvalidRegisterOperand(t *testing.T) {
+	tests := []string{
+		"LD [I], V1-VZ",
+		"LD V1-VZ, [I]",
+		"LD [I], V1-V3-V5",
+		"SHR V1, V5X",
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if _, err := Assemble(src); err == nil {
+				t.Fatalf("Assemble(%q) succeeded, want error", src)
+			}
+		})
+	}
+}
+
+// TestDisassembleLongLoadI guards against F000 NNNN (XO-CHIP's 4-byte
+// long "LD I" form) being decoded as two separate 2-byte instructions,
+// which previously produced a bogus "DW 0xF000" followed by whatever the
+// address bytes happened to decode to.
+func TestDisassembleLongLoadI(t *testing.T) {
+	rom := []byte{0xF0, 0x00, 0x12, 0x34}
+	instructions, err := Disassemble(rom, defaultOrigin)
+	if err != nil {
+		t.Fatalf("Disassemble: %v", err)
+	}
+	if len(instructions) != 1 {
+		t.Fatalf("Disassemble(F000 1234) produced %d instructions, want 1: %+v", len(instructions), instructions)
+	}
+	if want := "LD I, LONG 0x1234"; instructions[0].Text != want {
+		t.Fatalf("Disassemble(F000 1234) = %q, want %q", instructions[0].Text, want)
+	}
+
+	reassembled, err := Assemble(instructions[0].Text)
+	if err != nil {
+		t.Fatalf("Assemble(%q): %v", instructions[0].Text, err)
+	}
+	if string(reassembled) != string(rom) {
+		t.Fatalf("Assemble(%q) = % X, want % X", instructions[0].Text, reassembled, rom)
+	}
+}
@@ -0,0 +1,206 @@
+package asm
+
+import (
+	"fmt"
+
+	"github.com/jmarthernandez/chip8"
+)
+
+// Instruction is a single decoded instruction produced by Disassemble.
+type Instruction struct {
+	Addr   uint16
+	Opcode uint16
+	Text   string // reconstructed mnemonic source, e.g. "JP loc_0204"
+}
+
+// Disassemble walks rom as a sequence of CHIP-8 opcodes loaded at origin,
+// decoding each one back into Cowgod-style mnemonic source, extended with
+// the SuperChip/XO-CHIP opcodes. Every instruction is 2 bytes except
+// XO-CHIP's F000 NNNN long load, which is 4. Jump and call targets
+// (1nnn/2nnn) are resolved into synthetic "loc_XXXX" labels rather than
+// printed as bare addresses.
+func Disassemble(rom []byte, origin uint16) ([]Instruction, error) {
+	if len(rom)%2 != 0 {
+		return nil, fmt.Errorf("rom length %d is not a multiple of 2", len(rom))
+	}
+
+	labels := map[uint16]string{}
+	for i := 0; i+1 < len(rom); {
+		opcode := uint16(rom[i])<<8 | uint16(rom[i+1])
+		switch opcode & 0xF000 {
+		case 0x1000, 0x2000:
+			target := opcode & 0x0FFF
+			if _, ok := labels[target]; !ok {
+				labels[target] = fmt.Sprintf("loc_%04X", target)
+			}
+		}
+		if opcode == 0xF000 && i+3 < len(rom) {
+			i += 4
+			continue
+		}
+		i += 2
+	}
+
+	out := make([]Instruction, 0, len(rom)/2)
+	for i := 0; i+1 < len(rom); {
+		addr := origin + uint16(i)
+		opcode := uint16(rom[i])<<8 | uint16(rom[i+1])
+		if opcode == 0xF000 && i+3 < len(rom) {
+			nnnn := uint16(rom[i+2])<<8 | uint16(rom[i+3])
+			out = append(out, Instruction{
+				Addr:   addr,
+				Opcode: opcode,
+				Text:   fmt.Sprintf("LD I, LONG 0x%04X", nnnn),
+			})
+			i += 4
+			continue
+		}
+		out = append(out, Instruction{
+			Addr:   addr,
+			Opcode: opcode,
+			Text:   mnemonic(addr, opcode, labels),
+		})
+		i += 2
+	}
+	return out, nil
+}
+
+func mnemonic(addr, opcode uint16, labels map[uint16]string) string {
+	nnn := opcode & 0x0FFF
+	kk := byte(opcode)
+	n := opcode & 0x000F
+	x := chip8.XNib(opcode)
+	y := chip8.YNib(opcode)
+
+	target := func(a uint16) string {
+		if l, ok := labels[a]; ok {
+			return l
+		}
+		return fmt.Sprintf("0x%03X", a)
+	}
+
+	switch opcode & 0xF000 {
+	case 0x0000:
+		switch {
+		case opcode == 0x00E0:
+			return "CLS"
+		case opcode == 0x00EE:
+			return "RET"
+		case opcode&0xFFF0 == 0x00C0:
+			return fmt.Sprintf("SCD 0x%X", opcode&0x000F)
+		case opcode == 0x00FB:
+			return "SCR"
+		case opcode == 0x00FC:
+			return "SCL"
+		case opcode == 0x00FD:
+			return "EXIT"
+		case opcode == 0x00FE:
+			return "LOW"
+		case opcode == 0x00FF:
+			return "HIGH"
+		default:
+			return fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0x1000:
+		return fmt.Sprintf("JP %s", target(nnn))
+	case 0x2000:
+		return fmt.Sprintf("CALL %s", target(nnn))
+	case 0x3000:
+		return fmt.Sprintf("SE V%X, 0x%02X", x, kk)
+	case 0x4000:
+		return fmt.Sprintf("SNE V%X, 0x%02X", x, kk)
+	case 0x5000:
+		switch opcode & 0xF00F {
+		case 0x5000:
+			return fmt.Sprintf("SE V%X, V%X", x, y)
+		case 0x5002:
+			return fmt.Sprintf("LD [I], V%X-V%X", x, y)
+		case 0x5003:
+			return fmt.Sprintf("LD V%X-V%X, [I]", x, y)
+		default:
+			return fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0x6000:
+		return fmt.Sprintf("LD V%X, 0x%02X", x, kk)
+	case 0x7000:
+		return fmt.Sprintf("ADD V%X, 0x%02X", x, kk)
+	case 0x8000:
+		switch opcode & 0xF00F {
+		case 0x8000:
+			return fmt.Sprintf("LD V%X, V%X", x, y)
+		case 0x8001:
+			return fmt.Sprintf("OR V%X, V%X", x, y)
+		case 0x8002:
+			return fmt.Sprintf("AND V%X, V%X", x, y)
+		case 0x8003:
+			return fmt.Sprintf("XOR V%X, V%X", x, y)
+		case 0x8004:
+			return fmt.Sprintf("ADD V%X, V%X", x, y)
+		case 0x8005:
+			return fmt.Sprintf("SUB V%X, V%X", x, y)
+		case 0x8006:
+			return fmt.Sprintf("SHR V%X, V%X", x, y)
+		case 0x8007:
+			return fmt.Sprintf("SUBN V%X, V%X", x, y)
+		case 0x800E:
+			return fmt.Sprintf("SHL V%X, V%X", x, y)
+		default:
+			return fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0x9000:
+		return fmt.Sprintf("SNE V%X, V%X", x, y)
+	case 0xA000:
+		return fmt.Sprintf("LD I, %s", target(nnn))
+	case 0xB000:
+		return fmt.Sprintf("JP V0, %s", target(nnn))
+	case 0xC000:
+		return fmt.Sprintf("RND V%X, 0x%02X", x, kk)
+	case 0xD000:
+		return fmt.Sprintf("DRW V%X, V%X, 0x%X", x, y, n)
+	case 0xE000:
+		switch opcode & 0xF0FF {
+		case 0xE09E:
+			return fmt.Sprintf("SKP V%X", x)
+		case 0xE0A1:
+			return fmt.Sprintf("SKNP V%X", x)
+		default:
+			return fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0xF000:
+		// F000 itself (the XO-CHIP long load) is decoded by Disassemble
+		// before mnemonic is ever called, since it needs the two
+		// trailing address bytes this function doesn't have access to.
+		switch opcode & 0xF0FF {
+		case 0xF001:
+			return fmt.Sprintf("PLANE 0x%X", x)
+		case 0xF007:
+			return fmt.Sprintf("LD V%X, DT", x)
+		case 0xF00A:
+			return fmt.Sprintf("LD V%X, K", x)
+		case 0xF015:
+			return fmt.Sprintf("LD DT, V%X", x)
+		case 0xF018:
+			return fmt.Sprintf("LD ST, V%X", x)
+		case 0xF01E:
+			return fmt.Sprintf("ADD I, V%X", x)
+		case 0xF029:
+			return fmt.Sprintf("LD F, V%X", x)
+		case 0xF030:
+			return fmt.Sprintf("LD HF, V%X", x)
+		case 0xF033:
+			return fmt.Sprintf("LD B, V%X", x)
+		case 0xF055:
+			return fmt.Sprintf("LD [I], V%X", x)
+		case 0xF065:
+			return fmt.Sprintf("LD V%X, [I]", x)
+		case 0xF075:
+			return fmt.Sprintf("LD R, V%X", x)
+		case 0xF085:
+			return fmt.Sprintf("LD V%X, R", x)
+		default:
+			return fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	default:
+		return fmt.Sprintf("DW 0x%04X", opcode)
+	}
+}
@@ -0,0 +1,397 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// encodeInstruction assembles one CHIP-8 mnemonic with its operands into
+// the 16-bit opcode it represents, resolving any label operand against
+// symbols.
+func encodeInstruction(l sourceLine, addr uint16, symbols SymbolTable) (uint16, error) {
+	args := l.args
+	switch l.op {
+	case "CLS":
+		return 0x00E0, nil
+	case "RET":
+		return 0x00EE, nil
+	case "JP":
+		if len(args) == 2 && isReg(args[0], 0) {
+			target, err := resolveAddr(args[1], symbols)
+			if err != nil {
+				return 0, lineErr(l, err)
+			}
+			return 0xB000 | target, nil
+		}
+		target, err := resolveAddr(args[0], symbols)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x1000 | target, nil
+	case "CALL":
+		target, err := resolveAddr(args[0], symbols)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x2000 | target, nil
+	case "SE":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		if y, err := regNib(args[1]); err == nil {
+			return 0x5000 | x<<8 | y<<4, nil
+		}
+		kk, err := parseByte(args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x3000 | x<<8 | kk, nil
+	case "SNE":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		if y, err := regNib(args[1]); err == nil {
+			return 0x9000 | x<<8 | y<<4, nil
+		}
+		kk, err := parseByte(args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x4000 | x<<8 | kk, nil
+	case "LD":
+		return encodeLD(l, addr, symbols)
+	case "ADD":
+		if strings.EqualFold(args[0], "I") {
+			x, err := regNib(args[1])
+			if err != nil {
+				return 0, lineErr(l, err)
+			}
+			return 0xF01E | x<<8, nil
+		}
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		if y, err := regNib(args[1]); err == nil {
+			return 0x8004 | x<<8 | y<<4, nil
+		}
+		kk, err := parseByte(args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x7000 | x<<8 | kk, nil
+	case "OR":
+		return xy(l, 0x8001)
+	case "AND":
+		return xy(l, 0x8002)
+	case "XOR":
+		return xy(l, 0x8003)
+	case "SUB":
+		return xy(l, 0x8005)
+	case "SUBN":
+		return xy(l, 0x8007)
+	case "SHR":
+		return shift(l, 0x8006)
+	case "SHL":
+		return shift(l, 0x800E)
+	case "RND":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		kk, err := parseByte(args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xC000 | x<<8 | kk, nil
+	case "DRW":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		y, err := regNib(args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		n, err := parseNumber(args[2])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xD000 | x<<8 | y<<4 | uint16(n)&0x000F, nil
+	case "SKP":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xE09E | x<<8, nil
+	case "SKNP":
+		x, err := regNib(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xE0A1 | x<<8, nil
+	case "SCD":
+		// SuperChip: scroll the display down n pixels.
+		n, err := parseNumber(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0x00C0 | uint16(n)&0x000F, nil
+	case "SCR":
+		return 0x00FB, nil
+	case "SCL":
+		return 0x00FC, nil
+	case "EXIT":
+		return 0x00FD, nil
+	case "LOW":
+		return 0x00FE, nil
+	case "HIGH":
+		return 0x00FF, nil
+	case "PLANE":
+		// XO-CHIP: select which bit-plane(s) subsequent 00E0/Dxyn draw to.
+		n, err := parseNumber(args[0])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF001 | (uint16(n)&0xF)<<8, nil
+	default:
+		return 0, lineErr(l, fmt.Errorf("unknown mnemonic %q", l.op))
+	}
+}
+
+// xy encodes the common "OP Vx, Vy" -> base | x<<8 | y<<4 shape shared by
+// OR, AND, XOR, SUB, and SUBN.
+func xy(l sourceLine, base uint16) (uint16, error) {
+	x, err := regNib(l.args[0])
+	if err != nil {
+		return 0, lineErr(l, err)
+	}
+	y, err := regNib(l.args[1])
+	if err != nil {
+		return 0, lineErr(l, err)
+	}
+	return base | x<<8 | y<<4, nil
+}
+
+// shift encodes SHR/SHL, which take either "OP Vx" (Vy defaults to V0)
+// or the two-operand "OP Vx, Vy" form. Quirks.ShiftUsesVy (the
+// ChipClassic default) makes the interpreter actually read Vy at
+// runtime, so unlike other "Vx, Vy" mnemonics, Vy here is never a no-op
+// and must be encoded, not just Vx.
+func shift(l sourceLine, base uint16) (uint16, error) {
+	x, err := regNib(l.args[0])
+	if err != nil {
+		return 0, lineErr(l, err)
+	}
+	var y uint16
+	if len(l.args) > 1 {
+		y, err = regNib(l.args[1])
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+	}
+	return base | x<<8 | y<<4, nil
+}
+
+func encodeLD(l sourceLine, addr uint16, symbols SymbolTable) (uint16, error) {
+	args := l.args
+	if len(args) != 2 {
+		return 0, lineErr(l, fmt.Errorf("LD takes two operands"))
+	}
+	dst, src := args[0], args[1]
+
+	switch {
+	case strings.EqualFold(dst, "I"):
+		target, err := resolveAddr(src, symbols)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xA000 | target, nil
+	case strings.EqualFold(dst, "DT"):
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF015 | x<<8, nil
+	case strings.EqualFold(dst, "ST"):
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF018 | x<<8, nil
+	case strings.EqualFold(dst, "[I]"):
+		// XO-CHIP: "LD [I], Vx-Vy" (5xy2) saves an arbitrary register
+		// range instead of the fixed V0-Vx that Fx55 stores.
+		if xr, yr, ok := regRange(src); ok {
+			return 0x5002 | xr<<8 | yr<<4, nil
+		}
+		if strings.Contains(src, "-") {
+			return 0, lineErr(l, fmt.Errorf("invalid register range %q", src))
+		}
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF055 | x<<8, nil
+	case strings.EqualFold(dst, "F"):
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF029 | x<<8, nil
+	case strings.EqualFold(dst, "B"):
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF033 | x<<8, nil
+	case strings.EqualFold(dst, "HF"):
+		// SuperChip: LD HF, Vx - I = address of the big (8x10) font
+		// sprite for digit Vx.
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF030 | x<<8, nil
+	case strings.EqualFold(dst, "R"):
+		// SuperChip: LD R, Vx - persist V0-Vx into the HP48 user flags.
+		x, err := regNib(src)
+		if err != nil {
+			return 0, lineErr(l, err)
+		}
+		return 0xF075 | x<<8, nil
+	}
+
+	// XO-CHIP: "LD Vx-Vy, [I]" (5xy3) loads an arbitrary register range
+	// instead of the fixed V0-Vx that Fx65 reads.
+	if xr, yr, ok := regRange(dst); ok && strings.EqualFold(src, "[I]") {
+		return 0x5003 | xr<<8 | yr<<4, nil
+	}
+	if strings.Contains(dst, "-") {
+		return 0, lineErr(l, fmt.Errorf("invalid register range %q", dst))
+	}
+
+	x, err := regNib(dst)
+	if err != nil {
+		return 0, lineErr(l, err)
+	}
+	switch {
+	case strings.EqualFold(src, "DT"):
+		return 0xF007 | x<<8, nil
+	case strings.EqualFold(src, "K"):
+		return 0xF00A | x<<8, nil
+	case strings.EqualFold(src, "R"):
+		// SuperChip: LD Vx, R - restore V0-Vx from the HP48 user flags.
+		return 0xF085 | x<<8, nil
+	case strings.EqualFold(src, "[I]"):
+		return 0xF065 | x<<8, nil
+	}
+	if y, err := regNib(src); err == nil {
+		return 0x8000 | x<<8 | y<<4, nil
+	}
+	kk, err := parseByte(src)
+	if err != nil {
+		return 0, lineErr(l, err)
+	}
+	return 0x6000 | x<<8 | kk, nil
+}
+
+func resolveAddr(operand string, symbols SymbolTable) (uint16, error) {
+	if addr, ok := symbols[operand]; ok {
+		return addr & 0x0FFF, nil
+	}
+	v, err := parseNumber(operand)
+	if err != nil {
+		return 0, fmt.Errorf("undefined label or invalid address %q", operand)
+	}
+	return uint16(v) & 0x0FFF, nil
+}
+
+func regNib(operand string) (uint16, error) {
+	operand = strings.TrimSpace(operand)
+	if len(operand) < 2 || (operand[0] != 'V' && operand[0] != 'v') {
+		return 0, fmt.Errorf("not a register: %q", operand)
+	}
+	v, err := ParseHexDigit(operand[1:])
+	if err != nil {
+		return 0, fmt.Errorf("not a register: %q", operand)
+	}
+	return uint16(v), nil
+}
+
+// isReg reports whether operand names register Vn.
+func isReg(operand string, n int) bool {
+	v, err := regNib(operand)
+	return err == nil && int(v) == n
+}
+
+// regRange parses a "Vx-Vy" register-range operand, used by XO-CHIP's
+// 5xy2/5xy3 save/load instructions in place of Fx55/Fx65's fixed V0-Vx.
+func regRange(operand string) (x, y uint16, ok bool) {
+	parts := strings.SplitN(operand, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	x, err := regNib(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	y, err = regNib(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// isLongLoadI reports whether l is XO-CHIP's 4-byte "LD I, LONG nnnn"
+// form (F000 NNNN) rather than the regular 2-byte "LD I, nnn" (Annn).
+// sizeOf and encode special-case it before reaching encodeInstruction,
+// since it is the only instruction in this ISA that isn't 2 bytes wide.
+func isLongLoadI(l sourceLine) bool {
+	if l.op != "LD" || len(l.args) != 2 || !strings.EqualFold(l.args[0], "I") {
+		return false
+	}
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(l.args[1])), "LONG ")
+}
+
+// parseLongAddr extracts the 16-bit address out of a "LONG nnnn" operand.
+func parseLongAddr(operand string) (uint16, error) {
+	operand = strings.TrimSpace(operand)
+	const prefix = "LONG "
+	if len(operand) <= len(prefix) || !strings.EqualFold(operand[:len(prefix)], prefix) {
+		return 0, fmt.Errorf("expected LONG nnnn, got %q", operand)
+	}
+	v, err := parseNumber(strings.TrimSpace(operand[len(prefix):]))
+	if err != nil {
+		return 0, fmt.Errorf("invalid long address %q", operand)
+	}
+	return uint16(v), nil
+}
+
+func parseByte(operand string) (uint16, error) {
+	v, err := parseNumber(operand)
+	if err != nil || v > 0xFF {
+		return 0, fmt.Errorf("invalid byte operand %q", operand)
+	}
+	return uint16(v), nil
+}
+
+// ParseHexDigit parses a single hex digit (0-F), used for the register
+// number in a Vx operand.
+func ParseHexDigit(s string) (byte, error) {
+	if len(s) != 1 {
+		return 0, fmt.Errorf("invalid hex digit %q", s)
+	}
+	var v uint64
+	n, err := fmt.Sscanf(strings.ToUpper(s), "%X", &v)
+	if err != nil || n != 1 || v > 0xF {
+		return 0, fmt.Errorf("invalid hex digit %q", s)
+	}
+	return byte(v), nil
+}
+
+func lineErr(l sourceLine, err error) error {
+	return fmt.Errorf("line %d: %w", l.lineNo, err)
+}
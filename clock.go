@@ -0,0 +1,68 @@
+package chip8
+
+// DefaultIPF returns the instructions-per-frame a variant's ROMs are
+// typically authored against, assuming 60 frames per second: 11 for the
+// original COSMAC VIP, 30 for SuperChip, and 1000 for XO-CHIP, which
+// expects a much faster host interpreter.
+func DefaultIPF(variant Variant) int {
+	switch variant {
+	case SuperChip:
+		return 30
+	case XOChip:
+		return 1000
+	default:
+		return 11
+	}
+}
+
+// Clock controls how many instructions RunFrame executes per 60Hz frame,
+// independent of however often a front-end actually calls RunFrame. This
+// is what lets a ROM run at correct speed whether the host renders at
+// 30, 60, or 144 FPS.
+type Clock struct {
+	// hz is the number of instructions to execute per second at 1x
+	// speed.
+	hz int
+	// scale multiplies hz; 1 is normal speed, >1 is turbo, <1 is
+	// slow-motion.
+	scale float64
+}
+
+// NewClock returns a Clock running at variant's default speed.
+func NewClock(variant Variant) Clock {
+	return Clock{hz: DefaultIPF(variant) * 60, scale: 1}
+}
+
+// SetSpeed sets the clock's base rate in instructions per second,
+// overriding the variant's default. It's independent of how often the
+// front-end calls RunFrame.
+func (c *Clock) SetSpeed(hz int) {
+	c.hz = hz
+}
+
+// Turbo runs the CPU at 4x its base speed (the rate set by SetSpeed or
+// the variant default); calling it again does not speed up further.
+func (c *Clock) Turbo() {
+	c.scale = 4
+}
+
+// SlowMotion runs the CPU at 1/4 its base speed; calling it again does
+// not slow down further.
+func (c *Clock) SlowMotion() {
+	c.scale = 0.25
+}
+
+// Normal resets the clock to 1x speed, undoing Turbo/SlowMotion.
+func (c *Clock) Normal() {
+	c.scale = 1
+}
+
+// instructionsPerFrame returns how many instructions RunFrame should
+// execute this frame, assuming 60 frames per second.
+func (c *Clock) instructionsPerFrame() int {
+	ipf := int(float64(c.hz) / 60 * c.scale)
+	if ipf < 1 {
+		return 1
+	}
+	return ipf
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmarthernandez/chip8"
+)
+
+// errQuit is returned from PollInput (or, for the sdl backend, Update)
+// when the user asked to quit.
+var errQuit = errors.New("quit requested")
+
+// Backend renders a Display and feeds host key events into a Keyboard.
+// PollInput returns a non-nil error when the backend wants the run loop
+// to stop, e.g. the window was closed or Esc was pressed.
+type Backend interface {
+	PollInput(kb *chip8.Keyboard) error
+	Render(d *chip8.Display)
+	Close()
+}
+
+// NewBackend constructs the Backend named by --backend.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "terminal":
+		return newTerminalBackend()
+	case "headless":
+		return newHeadlessBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", name)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "github.com/jmarthernandez/chip8"
+
+// headlessBackend discards rendering and reports no input; it exists so
+// tests (and CI) can drive the CPU without a real display or terminal.
+type headlessBackend struct{}
+
+func newHeadlessBackend() *headlessBackend {
+	return &headlessBackend{}
+}
+
+func (h *headlessBackend) PollInput(kb *chip8.Keyboard) error { return nil }
+func (h *headlessBackend) Render(d *chip8.Display)            {}
+func (h *headlessBackend) Close()                             {}
@@ -0,0 +1,71 @@
+//go:build sdl
+
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jmarthernandez/chip8"
+)
+
+// scale is the number of host pixels drawn per CHIP-8 pixel.
+const scale = 10
+
+// ebitenKeymap mirrors the terminal keymap onto ebiten key codes.
+var ebitenKeymap = map[ebiten.Key]byte{
+	ebiten.Key1: 0x1, ebiten.Key2: 0x2, ebiten.Key3: 0x3, ebiten.Key4: 0xC,
+	ebiten.KeyQ: 0x4, ebiten.KeyW: 0x5, ebiten.KeyE: 0x6, ebiten.KeyR: 0xD,
+	ebiten.KeyA: 0x7, ebiten.KeyS: 0x8, ebiten.KeyD: 0x9, ebiten.KeyF: 0xE,
+	ebiten.KeyZ: 0xA, ebiten.KeyX: 0x0, ebiten.KeyC: 0xB, ebiten.KeyV: 0xF,
+}
+
+// game adapts a *chip8.CPU to the ebiten.Game interface, running the CPU
+// tick loop from Update and blitting the CPU's Display from Draw.
+type game struct {
+	cpu  *chip8.CPU
+	quit bool
+}
+
+func (g *game) Update() error {
+	for key, chip8Key := range ebitenKeymap {
+		if ebiten.IsKeyPressed(key) {
+			g.cpu.Keyboard.Press(chip8Key)
+		} else {
+			g.cpu.Keyboard.Release(chip8Key)
+		}
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		g.quit = true
+	}
+	if g.quit {
+		return errQuit
+	}
+	// ebiten calls Update 60 times per second by default, which is
+	// exactly the frame rate RunFrame's Clock assumes.
+	g.cpu.RunFrame()
+	return nil
+}
+
+func (g *game) Draw(screen *ebiten.Image) {
+	d := g.cpu.Display
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			if d.Pixel(x, y) != 0 {
+				screen.Set(x, y, color.White)
+			}
+		}
+	}
+}
+
+func (g *game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return g.cpu.Display.Width, g.cpu.Display.Height
+}
+
+// runSDL drives cpu to completion through ebiten's own run loop, with
+// ebiten acting as the "sdl" --backend.
+func runSDL(cpu *chip8.CPU) error {
+	ebiten.SetWindowSize(chip8.DisplayWidth*scale, chip8.DisplayHeight*scale)
+	ebiten.SetWindowTitle("chip8")
+	return ebiten.RunGame(&game{cpu: cpu})
+}
@@ -0,0 +1,16 @@
+//go:build !sdl
+
+package main
+
+import (
+	"errors"
+
+	"github.com/jmarthernandez/chip8"
+)
+
+// runSDL is replaced by backend_sdl.go when this binary is built with
+// -tags sdl (which pulls in ebiten). Without that tag we fail loudly
+// instead of silently falling back to another backend.
+func runSDL(cpu *chip8.CPU) error {
+	return errors.New("binary was built without sdl support; rebuild with -tags sdl")
+}
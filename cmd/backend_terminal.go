@@ -0,0 +1,90 @@
+//go:build terminal
+
+package main
+
+import (
+	"github.com/jmarthernandez/chip8"
+	"github.com/nsf/termbox-go"
+)
+
+// keymap maps CHIP-8 keys 0x0-0xF onto the COSMAC VIP keypad layout most
+// ROMs assume:
+//
+//	1 2 3 C        1 2 3 4
+//	4 5 6 D   ->   Q W E R
+//	7 8 9 E        A S D F
+//	A 0 B F        Z X C V
+var keymap = map[rune]byte{
+	'1': 0x1, '2': 0x2, '3': 0x3, '4': 0xC,
+	'q': 0x4, 'w': 0x5, 'e': 0x6, 'r': 0xD,
+	'a': 0x7, 's': 0x8, 'd': 0x9, 'f': 0xE,
+	'z': 0xA, 'x': 0x0, 'c': 0xB, 'v': 0xF,
+}
+
+// terminalBackend renders the display as block characters with termbox
+// and polls key events from a background goroutine between frames.
+type terminalBackend struct {
+	events chan termbox.Event
+}
+
+func newTerminalBackend() (*terminalBackend, error) {
+	if err := termbox.Init(); err != nil {
+		return nil, err
+	}
+	termbox.SetInputMode(termbox.InputEsc)
+
+	t := &terminalBackend{events: make(chan termbox.Event, 16)}
+	go func() {
+		for {
+			ev := termbox.PollEvent()
+			t.events <- ev
+			if ev.Type == termbox.EventKey && ev.Key == termbox.KeyEsc {
+				return
+			}
+		}
+	}()
+	return t, nil
+}
+
+// PollInput drains whatever key events have arrived since the last call.
+// The terminal has no key-up events, so pressed keys stay "down" until
+// the next key comes in; good enough for turn-based ROMs, imprecise for
+// ones that expect true hold/release.
+func (t *terminalBackend) PollInput(kb *chip8.Keyboard) error {
+	for {
+		select {
+		case ev := <-t.events:
+			if ev.Type != termbox.EventKey {
+				continue
+			}
+			if ev.Key == termbox.KeyEsc {
+				return errQuit
+			}
+			if key, ok := keymap[ev.Ch]; ok {
+				for k := byte(0); k < 16; k++ {
+					kb.Release(k)
+				}
+				kb.Press(key)
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (t *terminalBackend) Render(d *chip8.Display) {
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			ch := ' '
+			if d.Pixel(x, y) != 0 {
+				ch = '█'
+			}
+			termbox.SetCell(x, y, ch, termbox.ColorWhite, termbox.ColorDefault)
+		}
+	}
+	termbox.Flush()
+}
+
+func (t *terminalBackend) Close() {
+	termbox.Close()
+}
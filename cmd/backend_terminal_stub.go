@@ -0,0 +1,14 @@
+//go:build !terminal
+
+package main
+
+import "errors"
+
+// newTerminalBackend is replaced by backend_terminal.go when this binary
+// is built with -tags terminal (which pulls in termbox-go). Without that
+// tag we fail loudly instead of silently falling back to another
+// backend; --backend=headless is the no-dependency default for a clean
+// checkout.
+func newTerminalBackend() (Backend, error) {
+	return nil, errors.New("binary was built without terminal support; rebuild with -tags terminal")
+}
@@ -0,0 +1,34 @@
+// Command chip8asm assembles CHIP-8 source into a ROM image.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"github.com/jmarthernandez/chip8/asm"
+)
+
+func main() {
+	inPtr := flag.String("in", "", "path to assembly source")
+	outPtr := flag.String("out", "a.ch8", "path to write the assembled ROM")
+	flag.Parse()
+
+	if *inPtr == "" {
+		log.Fatal("missing -in")
+	}
+
+	src, err := ioutil.ReadFile(*inPtr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rom, err := asm.Assemble(string(src))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*outPtr, rom, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
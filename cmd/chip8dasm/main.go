@@ -0,0 +1,35 @@
+// Command chip8dasm disassembles a CHIP-8 ROM image back into source.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/jmarthernandez/chip8/asm"
+)
+
+func main() {
+	inPtr := flag.String("in", "", "path to ROM")
+	originPtr := flag.Uint("origin", 0x200, "load address the ROM is disassembled relative to")
+	flag.Parse()
+
+	if *inPtr == "" {
+		log.Fatal("missing -in")
+	}
+
+	rom, err := ioutil.ReadFile(*inPtr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	instructions, err := asm.Disassemble(rom, uint16(*originPtr))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, ins := range instructions {
+		fmt.Printf("%03X: %04X  %s\n", ins.Addr, ins.Opcode, ins.Text)
+	}
+}
@@ -0,0 +1,156 @@
+// Command chip8dbg is a tcell-based TUI debugger for the chip8 package:
+// panes for registers, the call stack, disassembly around PC, and hex
+// memory, with keys to step, step-over, and run-to-cursor.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jmarthernandez/chip8"
+	"github.com/jmarthernandez/chip8/debug"
+)
+
+func main() {
+	pathPtr := flag.String("path", "roms/invaders", "path to ROM")
+	flag.Parse()
+
+	rom, err := ioutil.ReadFile(*pathPtr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cpu := chip8.NewCPU()
+	cpu.LoadRom(rom)
+	dbg := debug.NewDebugger(&cpu)
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := screen.Init(); err != nil {
+		log.Fatal(err)
+	}
+	defer screen.Fini()
+
+	var cursor uint16
+	draw(screen, dbg, cursor)
+
+	for {
+		ev := screen.PollEvent()
+		key, ok := ev.(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+
+		switch key.Key() {
+		case tcell.KeyEscape, tcell.KeyCtrlC:
+			return
+		case tcell.KeyF10: // step
+			dbg.Step()
+		case tcell.KeyF11: // step-over
+			dbg.StepOver()
+		case tcell.KeyF9: // run to cursor
+			dbg.RunToCursor(cursor)
+		default:
+			switch key.Rune() {
+			case 's':
+				dbg.Step()
+			case 'o':
+				dbg.StepOver()
+			case 'c':
+				dbg.Continue()
+			case 'b':
+				dbg.AddBreakpoint(dbg.CPU.PC)
+			case 'r':
+				dbg.Rewind()
+			case 'q':
+				return
+			}
+		}
+
+		draw(screen, dbg, cursor)
+	}
+}
+
+// draw renders the registers, stack, disassembly-around-PC, and hex
+// memory panes into the four quadrants of the terminal.
+func draw(screen tcell.Screen, dbg *debug.Debugger, cursor uint16) {
+	screen.Clear()
+	w, h := screen.Size()
+	half := w / 2
+	top := h / 2
+
+	drawRegisters(screen, dbg, 0, 0, half, top)
+	drawStack(screen, dbg, half, 0, w-half, top)
+	drawDisassembly(screen, dbg, 0, top, half, h-top)
+	drawMemory(screen, dbg, half, top, w-half, h-top)
+
+	screen.Show()
+}
+
+func drawRegisters(screen tcell.Screen, dbg *debug.Debugger, x, y, w, h int) {
+	printLine(screen, x, y, "-- registers --")
+	regs := dbg.Registers()
+	for i, v := range regs.V {
+		printLine(screen, x, y+1+i, fmt.Sprintf("V%X = 0x%02X", i, v))
+	}
+	printLine(screen, x, y+17, fmt.Sprintf("I  = 0x%03X  PC = 0x%03X", regs.I, regs.PC))
+	printLine(screen, x, y+18, fmt.Sprintf("SP = 0x%02X   DT = 0x%02X  ST = 0x%02X", regs.SP, regs.DT, regs.ST))
+}
+
+func drawStack(screen tcell.Screen, dbg *debug.Debugger, x, y, w, h int) {
+	printLine(screen, x, y, "-- stack --")
+	regs := dbg.Registers()
+	for i := 0; i < int(regs.SP) && i < len(regs.Stack); i++ {
+		printLine(screen, x, y+1+i, fmt.Sprintf("%X: 0x%03X", i, regs.Stack[i]))
+	}
+}
+
+func drawDisassembly(screen tcell.Screen, dbg *debug.Debugger, x, y, w, h int) {
+	printLine(screen, x, y, "-- disassembly --")
+	regs := dbg.Registers()
+	start := uint16(0)
+	if regs.PC > 10 {
+		start = regs.PC - 10
+	}
+	end := start + 19 // 20 bytes = 10 instructions
+	instructions, err := dbg.DisassembleWindow(start, end)
+	if err != nil {
+		printLine(screen, x, y+1, err.Error())
+		return
+	}
+	for i, ins := range instructions {
+		marker := "  "
+		if ins.Addr == regs.PC {
+			marker = "->"
+		}
+		printLine(screen, x, y+1+i, fmt.Sprintf("%s %03X: %s", marker, ins.Addr, ins.Text))
+	}
+}
+
+func drawMemory(screen tcell.Screen, dbg *debug.Debugger, x, y, w, h int) {
+	printLine(screen, x, y, "-- memory --")
+	regs := dbg.Registers()
+	start := regs.I &^ 0x000F
+	for row := 0; row < h-1; row++ {
+		addr := start + uint16(row*8)
+		if int(addr) >= len(regs.Memory) {
+			break
+		}
+		line := fmt.Sprintf("%03X:", addr)
+		for col := 0; col < 8 && int(addr)+col < len(regs.Memory); col++ {
+			line += fmt.Sprintf(" %02X", regs.Memory[int(addr)+col])
+		}
+		printLine(screen, x, y+1+row, line)
+	}
+}
+
+func printLine(screen tcell.Screen, x, y int, s string) {
+	for i, r := range s {
+		screen.SetContent(x+i, y, r, nil, tcell.StyleDefault)
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"io/ioutil"
+	"log"
+	"time"
 
 	"github.com/jmarthernandez/chip8"
 )
@@ -15,9 +17,39 @@ func check(e error) {
 
 func main() {
 	pathPtr := flag.String("path", "roms/invaders", "path to ROM")
+	// headless is the default because it has no third-party
+	// dependencies; --backend=terminal needs a binary built with
+	// -tags terminal, and --backend=sdl needs -tags sdl.
+	backendPtr := flag.String("backend", "headless", "render/input backend: sdl|terminal|headless")
 	flag.Parse()
+
 	rom, err := ioutil.ReadFile(*pathPtr)
 	check(err)
+
 	cpu := chip8.NewCPU()
 	cpu.LoadRom(rom)
+
+	if *backendPtr == "sdl" {
+		if err := runSDL(&cpu); err != nil && err != errQuit {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	backend, err := NewBackend(*backendPtr)
+	check(err)
+	defer backend.Close()
+
+	// RunFrame paces instruction execution to the CPU's Clock, so we
+	// only need to call it once per 60Hz tick regardless of how fast
+	// this loop itself can spin.
+	ticker := time.NewTicker(time.Second / 60)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := backend.PollInput(cpu.Keyboard); err != nil {
+			break
+		}
+		cpu.RunFrame()
+		backend.Render(cpu.Display)
+	}
 }
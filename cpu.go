@@ -3,6 +3,7 @@ package chip8
 import (
 	"fmt"
 	"math/rand"
+	"time"
 )
 
 /*
@@ -31,38 +32,128 @@ CPU contains state of the emulated machine
 +---------------+= 0x000 (0) Start of Chip-8 RAM
 */
 type CPU struct {
-	Opcode uint16
-	Memory [4096]byte
-	V      [16]byte
-	I      uint16
-	PC     uint16
-	SP     byte
-	DT     byte
-	ST     byte
-	Stack  [16]uint16
+	Opcode   uint16
+	Memory   []byte
+	V        [16]byte
+	I        uint16
+	PC       uint16
+	SP       byte
+	DT       byte
+	ST       byte
+	Stack    [16]uint16
+	Display  *Display
+	Keyboard *Keyboard
+
+	// Halted is set by 00FD (XO-CHIP/SCHIP "exit"); front-ends should
+	// stop ticking the CPU once it's true.
+	Halted bool
+	// Flags holds the eight HP48 "user flags" Fx75/Fx85 persist V0-V7
+	// into, as real SCHIP interpreters do.
+	Flags [8]byte
+
+	Variant Variant
+	Quirks  Quirks
+	Clock   Clock
+
+	rng *rand.Rand
+	// drewThisFrame records whether Dxyn has drawn during the current
+	// RunFrame call, for the DisplayWait quirk to stall on.
+	drewThisFrame bool
+}
+
+// Options configures optional behavior for NewCPU. The zero value
+// selects ChipClassic with its default Quirks and a time-seeded RNG,
+// which is what you want outside of tests.
+type Options struct {
+	// Variant selects the memory size, default Quirks, and default
+	// Clock speed; see Variant. Defaults to ChipClassic.
+	Variant Variant
+	// Quirks overrides the variant's default quirk set. Leave the zero
+	// value to use DefaultQuirks(Variant).
+	Quirks *Quirks
+	// Rand backs the Cxkk (RND) instruction. Supply a seeded
+	// rand.New(rand.NewSource(seed)) to make RND reproducible under
+	// test; leave nil for a time-seeded default.
+	Rand *rand.Rand
 }
 
 // NewCPU returns a new CPU struct with default options and loads the fontset
-// into memory
-func NewCPU() CPU {
+// into memory. An Options value may be passed to override defaults, e.g.
+// to select a Variant or seed the RNG deterministically for tests.
+func NewCPU(opts ...Options) CPU {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
 	var cpu = CPU{
-		PC:     0x200,
-		Opcode: 0,
-		I:      0,
-		SP:     0,
+		PC:       0x200,
+		Opcode:   0,
+		I:        0,
+		SP:       0,
+		Memory:   make([]byte, opt.Variant.memorySize()),
+		Display:  NewDisplay(),
+		Keyboard: NewKeyboard(),
+		Variant:  opt.Variant,
+		Quirks:   DefaultQuirks(opt.Variant),
+		Clock:    NewClock(opt.Variant),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if opt.Quirks != nil {
+		cpu.Quirks = *opt.Quirks
+	}
+	if opt.Rand != nil {
+		cpu.rng = opt.Rand
 	}
 
 	for i, b := range FontSet {
 		cpu.Memory[i] = b
 	}
+	for i, b := range BigFontSet {
+		cpu.Memory[BigFontAddr+i] = b
+	}
 
 	return cpu
 }
 
+// Tick executes exactly one instruction and decrements the timers.
+// It's the deterministic building block used by the trace/test harness
+// and anything else that wants CPU execution and timers to advance
+// together in lockstep; interactive backends use RunFrame instead, which
+// paces execution to c.Clock and decrements timers once per frame.
+func (c *CPU) Tick() {
+	c.SetOpcode()
+	c.ExecuteOpcode()
+	c.decrementTimers()
+}
+
+// RunFrame executes one 60Hz frame's worth of instructions, as governed
+// by c.Clock, and decrements the timers once at the end of the frame.
+// Front-ends call this once per vsync instead of ticking the CPU on
+// every host frame, so ROMs run at correct speed regardless of host
+// FPS. If Quirks.DisplayWait is set, the frame stops early as soon as
+// Dxyn draws, matching the original COSMAC VIP's "wait for vblank"
+// behavior.
+func (c *CPU) RunFrame() {
+	c.drewThisFrame = false
+	for i := 0; i < c.Clock.instructionsPerFrame(); i++ {
+		if c.Halted {
+			break
+		}
+		c.SetOpcode()
+		c.ExecuteOpcode()
+		if c.Quirks.DisplayWait && c.drewThisFrame {
+			break
+		}
+	}
+	c.decrementTimers()
+}
+
 // LoadRom empties memory and loads program into memory
 func (c *CPU) LoadRom(r []byte) {
 	// Zero out memory after chip8 specific memory(fonts)
-	for m := 512; m < 4096; m++ {
+	for m := 512; m < len(c.Memory); m++ {
 		c.Memory[m] = 0x00
 	}
 
@@ -132,27 +223,42 @@ kk or byte - An 8-bit value, the lowest 8 bits of the instruction
 func (c *CPU) ExecuteOpcode() {
 	switch c.Opcode & 0xF000 {
 	case 0x0000:
-		switch c.Opcode {
-		case 0x00E0:
+		switch {
+		case c.Opcode == 0x00E0:
 			// Clear the display.
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			c.Display.Clear()
 			c.PC += 2
-			break
-		case 0x00EE:
+		case c.Opcode == 0x00EE:
 			// Return from a subroutine.
 			// The interpreter sets the program counter to the address at the
 			// top of the stack, then subtracts 1 from the stack pointer.
 			c.PC = c.Stack[c.SP]
 			c.SP--
-			break
+		case c.Opcode&0xFFF0 == 0x00C0:
+			// SuperChip: scroll the display down n pixels.
+			c.Display.ScrollDown(int(c.Opcode & 0x000F))
+			c.PC += 2
+		case c.Opcode == 0x00FB:
+			// SuperChip: scroll the display right 4 pixels.
+			c.Display.ScrollRight()
+			c.PC += 2
+		case c.Opcode == 0x00FC:
+			// SuperChip: scroll the display left 4 pixels.
+			c.Display.ScrollLeft()
+			c.PC += 2
+		case c.Opcode == 0x00FD:
+			// SuperChip: exit the interpreter.
+			c.Halted = true
+		case c.Opcode == 0x00FE:
+			// SuperChip: switch to low-resolution (64x32) mode.
+			c.Display.SetHiRes(false)
+			c.PC += 2
+		case c.Opcode == 0x00FF:
+			// SuperChip: switch to high-resolution (128x64) mode.
+			c.Display.SetHiRes(true)
+			c.PC += 2
 		default:
 			fmt.Printf("Unknown opcode [0x0000]: 0x%X\n", c.Opcode)
-			break
 		}
 	case 0x1000:
 		// Jump to location nnn.
@@ -186,14 +292,32 @@ func (c *CPU) ExecuteOpcode() {
 		c.PC += skipIf(vx != kk)
 		break
 	case 0x5000:
+		x := xNib(c.Opcode)
+		y := yNib(c.Opcode)
 		switch c.Opcode & 0xF00F {
 		case 0x5000:
 			// Skip next instruction if Vx = Vy.
 			// The interpreter compares register Vx to register Vy, and if they
 			// are equal, increments the program counter by 2.
-			vx := c.V[xNib(c.Opcode)]
-			vy := c.V[yNib(c.Opcode)]
-			c.PC += skipIf(vx == vy)
+			c.PC += skipIf(c.V[x] == c.V[y])
+			break
+		case 0x5002:
+			// XO-CHIP: save registers Vx through Vy (inclusive, in
+			// either direction) to memory starting at I, without
+			// modifying I.
+			for i, r := range registerRange(x, y) {
+				c.Memory[c.I+uint16(i)] = c.V[r]
+			}
+			c.PC += 2
+			break
+		case 0x5003:
+			// XO-CHIP: load registers Vx through Vy (inclusive, in
+			// either direction) from memory starting at I, without
+			// modifying I.
+			for i, r := range registerRange(x, y) {
+				c.V[r] = c.Memory[c.I+uint16(i)]
+			}
+			c.PC += 2
 			break
 		default:
 			fmt.Printf("Unknown opcode [0x0000]: 0x%X\n", c.Opcode)
@@ -230,6 +354,9 @@ func (c *CPU) ExecuteOpcode() {
 			// in Vx. A bitwise OR compares the corrseponding bits from two values, and
 			// if either bit is 1, then the same bit in the result is also 1. Otherwise, it is 0.
 			c.V[x] = c.V[x] | c.V[y]
+			if c.Quirks.VFResetOnLogic {
+				c.V[0xF] = 0
+			}
 			c.PC += 2
 			break
 		case 0x8002:
@@ -237,6 +364,9 @@ func (c *CPU) ExecuteOpcode() {
 			// in Vx. A bitwise AND compares the corrseponding bits from two values, and
 			// if both bits are 1, then the same bit in the result is also 1. Otherwise, it is 0.
 			c.V[x] = c.V[x] & c.V[y]
+			if c.Quirks.VFResetOnLogic {
+				c.V[0xF] = 0
+			}
 			c.PC += 2
 			break
 		case 0x8003:
@@ -245,6 +375,9 @@ func (c *CPU) ExecuteOpcode() {
 			// and if the bits are not both the same, then the corresponding bit in the
 			// result is set to 1. Otherwise, it is 0.
 			c.V[x] = c.V[x] ^ c.V[y]
+			if c.Quirks.VFResetOnLogic {
+				c.V[0xF] = 0
+			}
 			c.PC += 2
 			break
 		case 0x8004:
@@ -252,40 +385,57 @@ func (c *CPU) ExecuteOpcode() {
 			// The values of Vx and Vy are added together. If the result is greater
 			// than 8 bits (i.e., > 255,) VF is set to 1, otherwise 0. Only the lowest
 			// 8 bits of the result are kept, and stored in Vx.
-			c.V[x] = c.V[x] + c.V[y]
-			c.V[0xF] = ternary(c.V[x] > 255)
+			sum := uint16(c.V[x]) + uint16(c.V[y])
+			c.V[x] = byte(sum)
+			c.V[0xF] = ternary(sum > 0xFF)
 			c.PC += 2
 			break
 		case 0x8005:
 			// Set Vx = Vx - Vy, set VF = NOT borrow.
 			// If Vx > Vy, then VF is set to 1, otherwise 0. Then Vy is subtracted
 			// from Vx, and the results stored in Vx.
+			vf := ternary(c.V[x] > c.V[y])
 			c.V[x] = c.V[x] - c.V[y]
-			c.V[0xF] = ternary(c.V[x] > c.V[y])
+			c.V[0xF] = vf
 			c.PC += 2
 			break
 		case 0x8006:
 			// Set Vx = Vx SHR 1.
 			// If the least-significant bit of Vx is 1, then VF is set to 1,
 			// otherwise 0. Then Vx is divided by 2.
-			c.V[0xF] = ternary((c.V[x] & 0x0F) == 0x01)
-			c.V[x] = c.V[x] / 2
+			// Quirks.ShiftUsesVy shifts Vy into Vx instead of shifting Vx
+			// in place, matching the original COSMAC VIP interpreter.
+			src := c.V[x]
+			if c.Quirks.ShiftUsesVy {
+				src = c.V[y]
+			}
+			vf := ternary(src&0x01 == 0x01)
+			c.V[x] = src >> 1
+			c.V[0xF] = vf
 			c.PC += 2
 			break
 		case 0x8007:
 			// Set Vx = Vy - Vx, set VF = NOT borrow.
 			// If Vy > Vx, then VF is set to 1, otherwise 0. Then Vx is
 			// subtracted from Vy, and the results stored in Vx.
+			vf := ternary(c.V[y] > c.V[x])
 			c.V[x] = c.V[y] - c.V[x]
-			c.V[0xF] = ternary(c.V[y] > c.V[x])
+			c.V[0xF] = vf
 			c.PC += 2
 			break
 		case 0x800E:
 			// Set Vx = Vx SHL 1.
-			// If the least-significant bit of Vx is 1, then VF is set to 1,
-			// otherwise 0. Then Vx is divided by 2.
-			c.V[0xF] = ternary((c.V[x] & 0x0F) == 0x01)
-			c.V[x] = c.V[x] * 2
+			// If the most-significant bit of Vx is 1, then VF is set to 1,
+			// otherwise 0. Then Vx is multiplied by 2.
+			// Quirks.ShiftUsesVy shifts Vy into Vx instead of shifting Vx
+			// in place, matching the original COSMAC VIP interpreter.
+			src := c.V[x]
+			if c.Quirks.ShiftUsesVy {
+				src = c.V[y]
+			}
+			vf := ternary(src&0x80 == 0x80)
+			c.V[x] = src << 1
+			c.V[0xF] = vf
 			c.PC += 2
 			break
 		default:
@@ -316,7 +466,13 @@ func (c *CPU) ExecuteOpcode() {
 	case 0xB000:
 		// Jump to location nnn + V0.
 		// The program counter is set to nnn plus the value of V0.
-		c.PC = uint16(c.V[0]) + (c.Opcode & 0x0FFF)
+		// Quirks.JumpUsesVxNN instead adds the top nibble of nnn's own
+		// register (BXNN), the SCHIP/XO-CHIP behavior.
+		offsetReg := byte(0)
+		if c.Quirks.JumpUsesVxNN {
+			offsetReg = byte(xNib(c.Opcode))
+		}
+		c.PC = uint16(c.V[offsetReg]) + (c.Opcode & 0x0FFF)
 		break
 	case 0xC000:
 		// The interpreter generates a random number from 0 to 255, which is
@@ -324,7 +480,7 @@ func (c *CPU) ExecuteOpcode() {
 		// instruction 8xy2 for more information on AND.
 		x := xNib(c.Opcode)
 		kk := byte(c.Opcode)
-		r := byte(rand.Intn(255))
+		r := byte(c.rng.Intn(256))
 		c.V[x] = kk & r
 		c.PC += 2
 		break
@@ -337,17 +493,18 @@ func (c *CPU) ExecuteOpcode() {
 		// part of it is outside the coordinates of the display, it wraps around to
 		// the opposite side of the screen. See instruction 8xy3 for more information on XOR
 
-		// x := xNib(c.Opcode)
-		// y := yNib(c.Opcode)
-		// n := c.Opcode & 0x000F
-		// sprites := c.Memory[c.I : c.I+n]
-
-		fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-		//  _            _
-		// | |_ ___   __| | ___
-		// | __/ _ \ / _` |/ _ \
-		// | || (_) | (_| | (_) |
-		//  \__\___/ \__,_|\___/
+		x := c.V[xNib(c.Opcode)]
+		y := c.V[yNib(c.Opcode)]
+		n := c.Opcode & 0x000F
+
+		if n == 0 {
+			// SuperChip: Dxy0 draws a 16x16 sprite (32 bytes) instead.
+			c.V[0xF] = c.Display.Draw16x16(x, y, c.spritesByPlane(32))
+		} else {
+			c.V[0xF] = c.Display.Draw(x, y, c.spritesByPlane(n))
+		}
+		c.drewThisFrame = true
+		c.PC += 2
 		break
 	case 0xE000:
 		switch c.Opcode & 0xF0FF {
@@ -355,28 +512,34 @@ func (c *CPU) ExecuteOpcode() {
 			// Skip next instruction if key with the value of Vx is pressed.
 			// Checks the keyboard, and if the key corresponding to the value of
 			// Vx is currently in the down position, PC is increased by 2.
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			vx := c.V[xNib(c.Opcode)]
+			c.PC += skipIf(c.Keyboard.IsPressed(vx))
 			break
 		case 0xE0A1:
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			// Skip next instruction if key with the value of Vx is not pressed.
+			vx := c.V[xNib(c.Opcode)]
+			c.PC += skipIf(!c.Keyboard.IsPressed(vx))
 			break
 		default:
 			fmt.Printf("Unknown opcode [0x0000]: 0x%X\n", c.Opcode)
 			break
 		}
 	case 0xF000:
+		if c.Opcode == 0xF000 {
+			// XO-CHIP: LD I, NNNN - load a 16-bit address directly from
+			// the two bytes following this instruction.
+			c.I = uint16(c.Memory[c.PC+2])<<8 | uint16(c.Memory[c.PC+3])
+			c.PC += 4
+			break
+		}
 		x := xNib(c.Opcode)
 		switch c.Opcode & 0xF0FF {
+		case 0xF001:
+			// XO-CHIP: select which bit-plane(s) subsequent 00E0/Dxyn
+			// draw to; x is the plane bitmask (0-3).
+			c.Display.SetPlanes(byte(x))
+			c.PC += 2
+			break
 		case 0xF007:
 			// Set Vx = delay timer value.
 			// The value of DT is placed into Vx.
@@ -385,13 +548,15 @@ func (c *CPU) ExecuteOpcode() {
 			break
 		case 0xF00A:
 			// Wait for a key press, store the value of the key in Vx.
-			// All execution stops until a key is pressed, then the value of that key is stored in Vx.
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			// Non-blocking: if no key is down yet, PC is left pointing at
+			// this same instruction so the front-end's next RunFrame call
+			// re-executes it after polling input again. Blocking here
+			// would stall the goroutine that's also the only thing
+			// capable of supplying a key press.
+			if key, ok := c.Keyboard.AnyPressed(); ok {
+				c.V[x] = key
+				c.PC += 2
+			}
 			break
 		case 0xF015:
 			// Set delay timer = Vx.
@@ -415,32 +580,37 @@ func (c *CPU) ExecuteOpcode() {
 			// Set I = location of sprite for digit Vx.
 			// The value of I is set to the location for the hexadecimal sprite
 			// corresponding to the value of Vx.
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			c.I = uint16(c.V[x]) * 5
+			c.PC += 2
+			break
+		case 0xF030:
+			// SuperChip: set I = location of the big (8x10) sprite for
+			// digit Vx (0-9).
+			c.I = uint16(BigFontAddr) + uint16(c.V[x])*10
+			c.PC += 2
 			break
 		case 0xF033:
 			// Store BCD representation of Vx in memory locations I, I+1, and I+2.
 			// The interpreter takes the decimal value of Vx, and places the hundreds
 			// digit in memory at location in I, the tens digit at location I+1,
 			// and the ones digit at location I+2.
-			fmt.Printf("Not Implemented [0x0000]: 0x%X\n", c.Opcode)
-			//  _            _
-			// | |_ ___   __| | ___
-			// | __/ _ \ / _` |/ _ \
-			// | || (_) | (_| | (_) |
-			//  \__\___/ \__,_|\___/
+			c.Memory[c.I] = c.V[x] / 100
+			c.Memory[c.I+1] = (c.V[x] / 10) % 10
+			c.Memory[c.I+2] = c.V[x] % 10
+			c.PC += 2
 			break
 		case 0xF055:
 			// Store registers V0 through Vx in memory starting at location I.
 			// The interpreter copies the values of registers V0 through Vx into
 			// memory, starting at the address in I.
+			// Quirks.LoadStoreIncrementsI leaves I advanced past the
+			// stored range afterward, as the original interpreter did.
 			for i := uint16(0); i <= uint16(x); i++ {
 				c.Memory[c.I+i] = c.V[i]
 			}
+			if c.Quirks.LoadStoreIncrementsI {
+				c.I += uint16(x) + 1
+			}
 			c.PC += 2
 			break
 		case 0xF065:
@@ -450,6 +620,26 @@ func (c *CPU) ExecuteOpcode() {
 			for i := uint16(0); i <= uint16(x); i++ {
 				c.V[i] = c.Memory[c.I+i]
 			}
+			if c.Quirks.LoadStoreIncrementsI {
+				c.I += uint16(x) + 1
+			}
+			c.PC += 2
+			break
+		case 0xF075:
+			// SuperChip: persist V0 through Vx (x <= 7) into the HP48
+			// "user flags" that survive across runs via c.Flags.
+			for i := uint16(0); i <= uint16(x) && i < 8; i++ {
+				c.Flags[i] = c.V[i]
+			}
+			c.PC += 2
+			break
+		case 0xF085:
+			// SuperChip: restore V0 through Vx (x <= 7) from the HP48
+			// "user flags" in c.Flags.
+			for i := uint16(0); i <= uint16(x) && i < 8; i++ {
+				c.V[i] = c.Flags[i]
+			}
+			c.PC += 2
 			break
 		}
 	default:
@@ -475,9 +665,50 @@ func yNib(opcode uint16) uint16 {
 	return (opcode & 0x00F0) >> 4
 }
 
+// XNib and YNib expose xNib/yNib to other packages in this module (e.g.
+// chip8/asm) that need to decode the x/y register nibbles out of a raw
+// opcode without duplicating the bit math.
+func XNib(opcode uint16) uint16 { return xNib(opcode) }
+func YNib(opcode uint16) uint16 { return yNib(opcode) }
+
+// spritesByPlane reads the n sprite bytes starting at I for each active
+// display plane, for Dxyn/Dxy0 to hand to Display.Draw/Draw16x16. A
+// single active plane reads one n-byte sprite at I; XO-CHIP's dual-plane
+// draw (both planes selected) reads two independent n-byte sprites back
+// to back, plane 0 at I and plane 1 at I+n, per the XO-CHIP spec for
+// two-color sprites.
+func (c *CPU) spritesByPlane(n uint16) [2][]byte {
+	if c.Display.ActivePlanes() == 0x3 {
+		return [2][]byte{
+			c.Memory[c.I : c.I+n],
+			c.Memory[c.I+n : c.I+2*n],
+		}
+	}
+	sprite := c.Memory[c.I : c.I+n]
+	return [2][]byte{sprite, sprite}
+}
+
 func ternary(exp bool) byte {
 	if exp {
 		return 1
 	}
 	return 0
 }
+
+// registerRange returns the register indices from x to y inclusive,
+// counting upward if x <= y and downward otherwise, for XO-CHIP's
+// 5xy2/5xy3 range store/load.
+func registerRange(x, y uint16) []uint16 {
+	if x <= y {
+		out := make([]uint16, 0, y-x+1)
+		for i := x; i <= y; i++ {
+			out = append(out, i)
+		}
+		return out
+	}
+	out := make([]uint16, 0, x-y+1)
+	for i := x; i >= y; i-- {
+		out = append(out, i)
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+// Package debug wraps a chip8.CPU with breakpoints, single-stepping,
+// and time-travel rewind, for building debugger front-ends like
+// cmd/chip8dbg on top of.
+package debug
+
+import (
+	"github.com/jmarthernandez/chip8"
+	"github.com/jmarthernandez/chip8/asm"
+)
+
+// maxContinueSteps bounds Continue/StepOver so a ROM with no reachable
+// breakpoint (or a subroutine that never returns) can't hang the
+// debugger forever.
+const maxContinueSteps = 10_000_000
+
+// maxHistorySteps bounds how far Rewind can travel back. Without a cap,
+// a Continue/RunToCursor run against a ROM with no reachable breakpoint
+// would push a full Memory snapshot for every one of maxContinueSteps
+// steps and exhaust memory long before that loop bound is reached.
+const maxHistorySteps = 1000
+
+// Snapshot captures the CPU's memory, registers, timers, and stack,
+// taken before each Step so the debugger can travel back in time. The
+// display, keyboard, SuperChip user flags, and Halted are not captured,
+// so Rewind leaves them as they are.
+type Snapshot struct {
+	Memory []byte
+	V      [16]byte
+	I      uint16
+	PC     uint16
+	SP     byte
+	DT     byte
+	ST     byte
+	Stack  [16]uint16
+}
+
+// Debugger wraps a *chip8.CPU with breakpoints, watches, and
+// snapshot/restore for stepping through execution instruction by
+// instruction.
+type Debugger struct {
+	CPU *chip8.CPU
+
+	breakpoints map[uint16]bool
+	watches     map[uint16]bool
+
+	// history is a fixed-capacity ring buffer of the last maxHistorySteps
+	// snapshots, used as a bounded stack: pushHistory always overwrites
+	// the oldest entry once full, and popHistory always returns the most
+	// recently pushed one.
+	history     []Snapshot
+	historyNext int
+	historyLen  int
+}
+
+// NewDebugger returns a Debugger wrapping cpu.
+func NewDebugger(cpu *chip8.CPU) *Debugger {
+	return &Debugger{
+		CPU:         cpu,
+		breakpoints: map[uint16]bool{},
+		watches:     map[uint16]bool{},
+		history:     make([]Snapshot, maxHistorySteps),
+	}
+}
+
+// pushHistory records s as the most recent entry in the bounded rewind
+// history, evicting the oldest entry once history is full.
+func (d *Debugger) pushHistory(s Snapshot) {
+	d.history[d.historyNext] = s
+	d.historyNext = (d.historyNext + 1) % maxHistorySteps
+	if d.historyLen < maxHistorySteps {
+		d.historyLen++
+	}
+}
+
+// popHistory removes and returns the most recently pushed entry. It
+// reports false if there is no history left.
+func (d *Debugger) popHistory() (Snapshot, bool) {
+	if d.historyLen == 0 {
+		return Snapshot{}, false
+	}
+	d.historyNext = (d.historyNext - 1 + maxHistorySteps) % maxHistorySteps
+	d.historyLen--
+	return d.history[d.historyNext], true
+}
+
+// Snapshot captures the CPU's current state, deep-copying Memory so a
+// later mutation of the live CPU can't corrupt this snapshot.
+func (d *Debugger) Snapshot() Snapshot {
+	memory := make([]byte, len(d.CPU.Memory))
+	copy(memory, d.CPU.Memory)
+	return Snapshot{
+		Memory: memory,
+		V:      d.CPU.V,
+		I:      d.CPU.I,
+		PC:     d.CPU.PC,
+		SP:     d.CPU.SP,
+		DT:     d.CPU.DT,
+		ST:     d.CPU.ST,
+		Stack:  d.CPU.Stack,
+	}
+}
+
+// Restore replaces the CPU's state with a previously captured Snapshot.
+func (d *Debugger) Restore(s Snapshot) {
+	d.CPU.Memory = s.Memory
+	d.CPU.V = s.V
+	d.CPU.I = s.I
+	d.CPU.PC = s.PC
+	d.CPU.SP = s.SP
+	d.CPU.DT = s.DT
+	d.CPU.ST = s.ST
+	d.CPU.Stack = s.Stack
+}
+
+// Step executes exactly one instruction, pushing a pre-execution
+// snapshot onto the rewind history.
+func (d *Debugger) Step() {
+	d.pushHistory(d.Snapshot())
+	d.CPU.SetOpcode()
+	d.CPU.ExecuteOpcode()
+}
+
+// Rewind undoes the most recently stepped instruction. It reports false
+// if there is no history left to rewind into; history only reaches back
+// maxHistorySteps instructions.
+func (d *Debugger) Rewind() bool {
+	last, ok := d.popHistory()
+	if !ok {
+		return false
+	}
+	d.Restore(last)
+	return true
+}
+
+// AddBreakpoint stops Continue whenever PC reaches addr.
+func (d *Debugger) AddBreakpoint(addr uint16) {
+	d.breakpoints[addr] = true
+}
+
+// RemoveBreakpoint removes a previously added breakpoint.
+func (d *Debugger) RemoveBreakpoint(addr uint16) {
+	delete(d.breakpoints, addr)
+}
+
+// AddWatch marks addr as a watched memory location for a front-end to
+// highlight when it changes.
+func (d *Debugger) AddWatch(addr uint16) {
+	d.watches[addr] = true
+}
+
+// Watches returns the addresses currently being watched.
+func (d *Debugger) Watches() []uint16 {
+	out := make([]uint16, 0, len(d.watches))
+	for addr := range d.watches {
+		out = append(out, addr)
+	}
+	return out
+}
+
+// Continue steps the CPU until PC lands on a breakpoint, returning the
+// address it stopped at.
+func (d *Debugger) Continue() uint16 {
+	for i := 0; i < maxContinueSteps; i++ {
+		d.Step()
+		if d.breakpoints[d.CPU.PC] {
+			break
+		}
+	}
+	return d.CPU.PC
+}
+
+// RunToCursor steps the CPU until PC reaches addr, for a "run to
+// cursor" debugger action.
+func (d *Debugger) RunToCursor(addr uint16) uint16 {
+	for i := 0; i < maxContinueSteps && d.CPU.PC != addr; i++ {
+		d.Step()
+	}
+	return d.CPU.PC
+}
+
+// StepOver steps through a CALL (2nnn) until control returns to the
+// instruction after it, skipping over the whole subroutine rather than
+// descending into it. Any other instruction behaves like a plain Step.
+func (d *Debugger) StepOver() {
+	d.CPU.SetOpcode()
+	if d.CPU.Opcode&0xF000 != 0x2000 {
+		d.Step()
+		return
+	}
+
+	returnPC := d.CPU.PC + 2
+	targetSP := d.CPU.SP
+	for i := 0; i < maxContinueSteps; i++ {
+		d.Step()
+		if d.CPU.PC == returnPC && d.CPU.SP == targetSP {
+			return
+		}
+	}
+}
+
+// Registers returns a snapshot of the CPU's registers for a front-end to
+// render in a registers pane.
+func (d *Debugger) Registers() Snapshot {
+	return d.Snapshot()
+}
+
+// DisassembleWindow disassembles memory from start to end (inclusive)
+// for a front-end's disassembly pane.
+func (d *Debugger) DisassembleWindow(start, end uint16) ([]asm.Instruction, error) {
+	return asm.Disassemble(d.CPU.Memory[start:end+1], start)
+}
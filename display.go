@@ -0,0 +1,182 @@
+package chip8
+
+// DisplayWidth and DisplayHeight are the pixel dimensions of the classic
+// low-res CHIP-8 display; SuperChip's 00FE/00FF switch to double that.
+const (
+	DisplayWidth  = 64
+	DisplayHeight = 32
+)
+
+// Display is the pixel buffer Dxyn (and friends) draw into. SuperChip
+// adds a 128x64 high-res mode (SetHiRes); XO-CHIP adds a second
+// bit-plane that Fn01 (SetPlanes) selects between for dual-plane draws.
+// Classic and SuperChip ROMs only ever use plane 0.
+type Display struct {
+	Width, Height int
+
+	planes       [2][]byte // each flattened Width*Height, 0 or 1 per pixel
+	activePlanes byte      // bitmask: bit0 = plane 0, bit1 = plane 1
+}
+
+// NewDisplay returns a cleared, low-res (64x32), single-plane Display.
+func NewDisplay() *Display {
+	d := &Display{activePlanes: 0x1}
+	d.resize(DisplayWidth, DisplayHeight)
+	return d
+}
+
+func (d *Display) resize(w, h int) {
+	d.Width, d.Height = w, h
+	d.planes[0] = make([]byte, w*h)
+	d.planes[1] = make([]byte, w*h)
+}
+
+// SetHiRes switches between the classic 64x32 and SuperChip's 128x64
+// resolutions (00FE/00FF). Like real interpreters, this clears the
+// screen.
+func (d *Display) SetHiRes(hiRes bool) {
+	if hiRes {
+		d.resize(DisplayWidth*2, DisplayHeight*2)
+	} else {
+		d.resize(DisplayWidth, DisplayHeight)
+	}
+}
+
+// SetPlanes selects which of the two XO-CHIP bit-planes subsequent
+// Clear/Draw/Draw16x16 calls apply to (Fn01): bit0 selects plane 0, bit1
+// plane 1.
+func (d *Display) SetPlanes(mask byte) {
+	d.activePlanes = mask & 0x3
+}
+
+// ActivePlanes returns the plane bitmask last set by SetPlanes, so
+// callers can tell whether a draw is single-plane or dual-plane before
+// deciding how many sprite bytes to read out of memory.
+func (d *Display) ActivePlanes() byte {
+	return d.activePlanes
+}
+
+func (d *Display) at(plane, x, y int) byte {
+	return d.planes[plane][y*d.Width+x]
+}
+
+func (d *Display) set(plane, x, y int, v byte) {
+	d.planes[plane][y*d.Width+x] = v
+}
+
+// Pixel reports whether either plane is lit at (x, y); front-ends
+// composite the XO-CHIP planes into one visible pixel this way.
+func (d *Display) Pixel(x, y int) byte {
+	return d.planes[0][y*d.Width+x] | d.planes[1][y*d.Width+x]
+}
+
+// Clear blanks the currently selected planes. Backing instruction: 00E0.
+func (d *Display) Clear() {
+	d.eachActivePlane(func(p int) {
+		for i := range d.planes[p] {
+			d.planes[p][i] = 0
+		}
+	})
+}
+
+// ScrollDown moves the selected planes' pixels down n rows (00Cn).
+func (d *Display) ScrollDown(n int) {
+	d.eachActivePlane(func(p int) {
+		for y := d.Height - 1; y >= 0; y-- {
+			for x := 0; x < d.Width; x++ {
+				if src := y - n; src >= 0 {
+					d.set(p, x, y, d.at(p, x, src))
+				} else {
+					d.set(p, x, y, 0)
+				}
+			}
+		}
+	})
+}
+
+// ScrollRight moves the selected planes' pixels right 4 columns (00FB).
+func (d *Display) ScrollRight() { d.scrollX(4) }
+
+// ScrollLeft moves the selected planes' pixels left 4 columns (00FC).
+func (d *Display) ScrollLeft() { d.scrollX(-4) }
+
+func (d *Display) scrollX(n int) {
+	d.eachActivePlane(func(p int) {
+		row := make([]byte, d.Width)
+		for y := 0; y < d.Height; y++ {
+			for x := range row {
+				if src := x - n; src >= 0 && src < d.Width {
+					row[x] = d.at(p, src, y)
+				} else {
+					row[x] = 0
+				}
+			}
+			for x, v := range row {
+				d.set(p, x, y, v)
+			}
+		}
+	})
+}
+
+func (d *Display) eachActivePlane(fn func(plane int)) {
+	for p := 0; p < 2; p++ {
+		if d.activePlanes&(1<<uint(p)) != 0 {
+			fn(p)
+		}
+	}
+}
+
+// Draw XORs an n-byte sprite onto the selected planes at (x, y),
+// wrapping around the edges when part of the sprite falls off either
+// side. It returns 1 if any previously lit pixel in any selected plane
+// was erased, which callers store in VF as the collision flag.
+//
+// byPlane supplies the sprite bytes per plane: in classic/SuperChip
+// single-plane draws both entries are the same slice, but XO-CHIP
+// dual-plane draws (Fn01 mask 0x3) pass distinct bytes per plane, since
+// each plane carries its own half of a two-color sprite.
+func (d *Display) Draw(x, y byte, byPlane [2][]byte) byte {
+	collision := byte(0)
+	d.eachActivePlane(func(p int) {
+		for row, b := range byPlane[p] {
+			if d.drawByte(p, x, y, row, b, 0) {
+				collision = 1
+			}
+		}
+	})
+	return collision
+}
+
+// Draw16x16 draws a SuperChip 16x16 sprite (Dxy0): 32 bytes, two
+// (left/right) 8-bit columns per row. byPlane is as in Draw.
+func (d *Display) Draw16x16(x, y byte, byPlane [2][]byte) byte {
+	collision := byte(0)
+	d.eachActivePlane(func(p int) {
+		sprite := byPlane[p]
+		for row := 0; row < 16 && row*2+1 < len(sprite); row++ {
+			if d.drawByte(p, x, y, row, sprite[row*2], 0) {
+				collision = 1
+			}
+			if d.drawByte(p, x, y, row, sprite[row*2+1], 8) {
+				collision = 1
+			}
+		}
+	})
+	return collision
+}
+
+func (d *Display) drawByte(plane int, x, y byte, row int, b byte, xOffset int) bool {
+	collided := false
+	py := (int(y) + row) % d.Height
+	for bit := 0; bit < 8; bit++ {
+		if b&(0x80>>uint(bit)) == 0 {
+			continue
+		}
+		px := (int(x) + xOffset + bit) % d.Width
+		if d.at(plane, px, py) == 1 {
+			collided = true
+		}
+		d.set(plane, px, py, d.at(plane, px, py)^1)
+	}
+	return collided
+}
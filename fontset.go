@@ -0,0 +1,42 @@
+package chip8
+
+// FontSet holds the built-in hexadecimal digit sprites (0-F), five bytes
+// each, loaded into memory starting at address 0x000 so Fx29 can look
+// them up as I = 5 * digit.
+var FontSet = [80]byte{
+	0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
+	0x20, 0x60, 0x20, 0x20, 0x70, // 1
+	0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
+	0xF0, 0x10, 0xF0, 0x10, 0xF0, // 3
+	0x90, 0x90, 0xF0, 0x10, 0x10, // 4
+	0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
+	0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
+	0xF0, 0x10, 0x20, 0x40, 0x40, // 7
+	0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
+	0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
+	0xF0, 0x90, 0xF0, 0x90, 0x90, // A
+	0xE0, 0x90, 0xE0, 0x90, 0xE0, // B
+	0xF0, 0x80, 0x80, 0x80, 0xF0, // C
+	0xE0, 0x90, 0x90, 0x90, 0xE0, // D
+	0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
+	0xF0, 0x80, 0xF0, 0x80, 0x80, // F
+}
+
+// BigFontAddr is where BigFontSet is loaded into memory, right after
+// FontSet.
+const BigFontAddr = len(FontSet)
+
+// BigFontSet holds the SuperChip 8x10 big-digit sprites for 0-9, used by
+// Fx30. It's loaded into memory starting at BigFontAddr.
+var BigFontSet = [100]byte{
+	0x3C, 0x7E, 0xE7, 0xC3, 0xC3, 0xC3, 0xC3, 0xE7, 0x7E, 0x3C, // 0
+	0x18, 0x38, 0x58, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3C, // 1
+	0x7E, 0xFF, 0xC3, 0x06, 0x0C, 0x18, 0x30, 0x60, 0xFF, 0xFF, // 2
+	0x7E, 0xFF, 0xC3, 0x03, 0x0E, 0x0E, 0x03, 0xC3, 0xFF, 0x7E, // 3
+	0x06, 0x0E, 0x1E, 0x36, 0x66, 0xC6, 0xFF, 0xFF, 0x06, 0x06, // 4
+	0xFF, 0xFF, 0xC0, 0xFC, 0xFE, 0x03, 0x03, 0xC3, 0xFF, 0x7E, // 5
+	0x3E, 0x7C, 0xC0, 0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0x7E, 0x3C, // 6
+	0xFF, 0xFF, 0x03, 0x06, 0x0C, 0x18, 0x30, 0x30, 0x30, 0x30, // 7
+	0x7E, 0xFF, 0xC3, 0xC3, 0x7E, 0x7E, 0xC3, 0xC3, 0xFF, 0x7E, // 8
+	0x7E, 0xFF, 0xC3, 0xC3, 0x7F, 0x03, 0x03, 0xC3, 0xFF, 0x7E, // 9
+}
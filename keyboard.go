@@ -0,0 +1,42 @@
+package chip8
+
+// Keyboard is the 16-key CHIP-8 keypad (0x0-0xF). Backends translate
+// whatever physical keys the host reports into these logical key values
+// via Press/Release.
+type Keyboard struct {
+	keys [16]bool
+}
+
+// NewKeyboard returns a Keyboard with no keys held down.
+func NewKeyboard() *Keyboard {
+	return &Keyboard{}
+}
+
+// IsPressed reports whether key (0x0-0xF) is currently held down.
+// Backing instructions: Ex9E, ExA1.
+func (k *Keyboard) IsPressed(key byte) bool {
+	return k.keys[key&0xF]
+}
+
+// Press marks key as held down.
+func (k *Keyboard) Press(key byte) {
+	k.keys[key&0xF] = true
+}
+
+// Release marks key as no longer held down.
+func (k *Keyboard) Release(key byte) {
+	k.keys[key&0xF] = false
+}
+
+// AnyPressed reports whether any key is currently held down, and if so
+// which one (lowest key value wins when more than one is down). Fx0A
+// polls this once per instruction rather than blocking, so the CPU never
+// suspends the goroutine that's also responsible for feeding it input.
+func (k *Keyboard) AnyPressed() (key byte, ok bool) {
+	for i, pressed := range k.keys {
+		if pressed {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
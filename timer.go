@@ -0,0 +1,11 @@
+package chip8
+
+// decrementTimers decrements DT and ST by one, floored at zero.
+func (c *CPU) decrementTimers() {
+	if c.DT > 0 {
+		c.DT--
+	}
+	if c.ST > 0 {
+		c.ST--
+	}
+}
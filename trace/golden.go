@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jmarthernandez/chip8"
+)
+
+// TestAgainstTrace runs the ROM at romPath against golden line-delimited
+// JSON Records at expectedTracePath, failing t at the first instruction
+// whose actual trace diverges from the golden one. opts configures the
+// CPU the ROM runs on; golden ROMs that exercise Cxkk (RND) need a
+// seeded opts.Rand to make their trace reproducible.
+func TestAgainstTrace(t *testing.T, romPath, expectedTracePath string, opts chip8.Options) {
+	t.Helper()
+
+	rom, err := ioutil.ReadFile(romPath)
+	if err != nil {
+		t.Fatalf("reading rom: %v", err)
+	}
+	golden, err := os.Open(expectedTracePath)
+	if err != nil {
+		t.Fatalf("reading golden trace: %v", err)
+	}
+	defer golden.Close()
+
+	cpu := chip8.NewCPU(opts)
+	cpu.LoadRom(rom)
+
+	scanner := bufio.NewScanner(golden)
+	for i := 0; scanner.Scan(); i++ {
+		var want Record
+		if err := json.Unmarshal(scanner.Bytes(), &want); err != nil {
+			t.Fatalf("line %d: invalid golden record: %v", i, err)
+		}
+
+		got, err := step(&cpu)
+		if err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+
+		if !recordsEqual(got, want) {
+			t.Fatalf("instruction %d: got %+v, want %+v", i, got, want)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading golden trace: %v", err)
+	}
+}
+
+func recordsEqual(a, b Record) bool {
+	if a.PC != b.PC || a.Opcode != b.Opcode || a.Mnemonic != b.Mnemonic {
+		return false
+	}
+	return mapsEqual(a.Changed, b.Changed) && mapsEqual(a.MemWrites, b.MemWrites)
+}
+
+func mapsEqual(a, b map[string]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
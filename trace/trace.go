@@ -0,0 +1,75 @@
+// Package trace records what a chip8.CPU does instruction by
+// instruction, as a canonical, line-delimited JSON format, so opcode
+// regressions can be caught by diffing against a golden trace instead of
+// by eyeballing emulator output.
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jmarthernandez/chip8"
+	"github.com/jmarthernandez/chip8/asm"
+)
+
+// Record is one line of a trace: the opcode executed at PC, its
+// mnemonic, and whatever V registers or memory locations it wrote.
+type Record struct {
+	PC        uint16          `json:"pc"`
+	Opcode    uint16          `json:"opcode"`
+	Mnemonic  string          `json:"mnemonic"`
+	Changed   map[string]byte `json:"changed_registers,omitempty"`
+	MemWrites map[string]byte `json:"memory_writes,omitempty"`
+}
+
+// Run ticks cpu exactly steps times, writing one Record per instruction
+// as line-delimited JSON to w.
+func Run(cpu *chip8.CPU, steps int, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for i := 0; i < steps; i++ {
+		rec, err := step(cpu)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// step ticks cpu once and diffs its state before and after to build the
+// Record for that instruction.
+func step(cpu *chip8.CPU) (Record, error) {
+	pc := cpu.PC
+	beforeV := cpu.V
+	beforeMem := make([]byte, len(cpu.Memory))
+	copy(beforeMem, cpu.Memory)
+
+	cpu.Tick()
+
+	mnemonic := "???"
+	if instructions, err := asm.Disassemble(beforeMem[pc:pc+2], pc); err == nil && len(instructions) == 1 {
+		mnemonic = instructions[0].Text
+	}
+
+	rec := Record{PC: pc, Opcode: cpu.Opcode, Mnemonic: mnemonic}
+	for i := range cpu.V {
+		if cpu.V[i] != beforeV[i] {
+			if rec.Changed == nil {
+				rec.Changed = map[string]byte{}
+			}
+			rec.Changed[fmt.Sprintf("V%X", i)] = cpu.V[i]
+		}
+	}
+	for addr := range cpu.Memory {
+		if cpu.Memory[addr] != beforeMem[addr] {
+			if rec.MemWrites == nil {
+				rec.MemWrites = map[string]byte{}
+			}
+			rec.MemWrites[fmt.Sprintf("0x%03X", addr)] = cpu.Memory[addr]
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,21 @@
+package trace
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jmarthernandez/chip8"
+)
+
+// TestMiniROM exercises the golden-file harness against a tiny, hand
+// verified ROM (testdata/mini.ch8 / mini.trace.jsonl). Golden traces for
+// real test ROMs like IBM logo, BC_test, and corax+ belong here too, but
+// this environment has no network access to fetch those ROM binaries,
+// so only this synthetic fixture is checked in.
+//
+// The RNG is seeded so the trace is reproducible even though mini.ch8
+// doesn't happen to use Cxkk today.
+func TestMiniROM(t *testing.T) {
+	opts := chip8.Options{Rand: rand.New(rand.NewSource(1))}
+	TestAgainstTrace(t, "testdata/mini.ch8", "testdata/mini.trace.jsonl", opts)
+}
@@ -0,0 +1,65 @@
+package chip8
+
+// Variant selects which CHIP-8 dialect's memory size, default Quirks,
+// and default Clock speed the CPU uses. It does not gate opcodes:
+// ExecuteOpcode decodes the SuperChip and XO-CHIP instructions under
+// every variant, ChipClassic included.
+type Variant int
+
+const (
+	// ChipClassic is the original Cowgod-reference instruction set.
+	ChipClassic Variant = iota
+	// SuperChip adds the 00Cn/00FB/00FC/00FD/00FE/00FF/Dxy0/Fx30/Fx75/Fx85
+	// instructions and a 128x64 high-res display mode.
+	SuperChip
+	// XOChip adds SuperChip's instructions plus 5xy2/5xy3 register
+	// range store/load, F000 NNNN long I load, Fn01 plane select, and
+	// dual bit-plane draws, backed by 64KB of memory.
+	XOChip
+)
+
+// memorySize returns how much memory a CPU of this variant should
+// allocate; only XOChip needs more than the classic 4KB.
+func (v Variant) memorySize() int {
+	if v == XOChip {
+		return 65536
+	}
+	return 4096
+}
+
+// Quirks controls per-instruction behavior that real CHIP-8
+// interpreters disagree on; ROMs are often written against one
+// particular interpreter's quirks, so NewCPU lets callers pick.
+type Quirks struct {
+	// ShiftUsesVy makes 8xy6/8xyE shift Vy into Vx instead of shifting
+	// Vx in place, as the original COSMAC VIP interpreter did.
+	ShiftUsesVy bool
+	// LoadStoreIncrementsI makes Fx55/Fx65 leave I incremented by x+1
+	// afterward, as the original interpreter did.
+	LoadStoreIncrementsI bool
+	// JumpUsesVxNN makes Bnnn use the register named by the top nibble
+	// of nnn instead of always V0 (the SCHIP/XO-CHIP BXNN behavior).
+	JumpUsesVxNN bool
+	// VFResetOnLogic makes 8xy1/8xy2/8xy3 (OR/AND/XOR) clear VF to 0,
+	// as the original interpreter did.
+	VFResetOnLogic bool
+	// DisplayWait stalls Dxyn until the next 60Hz frame boundary, as
+	// the original COSMAC VIP did; most SCHIP/XO-CHIP ROMs expect it
+	// disabled so they can draw faster than 60Hz.
+	DisplayWait bool
+}
+
+// DefaultQuirks returns the quirk set ROMs written for variant typically
+// assume: the original interpreter's quirks for ChipClassic, and a
+// quirk-free CHIP-8 for SuperChip/XOChip.
+func DefaultQuirks(variant Variant) Quirks {
+	if variant == ChipClassic {
+		return Quirks{
+			ShiftUsesVy:          true,
+			LoadStoreIncrementsI: true,
+			VFResetOnLogic:       true,
+			DisplayWait:          true,
+		}
+	}
+	return Quirks{}
+}